@@ -0,0 +1,148 @@
+package main
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// hclEditor wraps an hclwrite.File so that JS-driven edits (setting an attribute, appending
+// or removing a block) can be applied to a parsed HCL file while preserving everything
+// hclConverter throws away on the way to JS: raw tokens, comments, and original formatting.
+//
+// Blocks are addressed by a path: an ordered slice of block addresses, each in the same
+// shape hclConverter emits for a block node (see exitBlock): {"type": "...", "labels":
+// [...]}. The root body is addressed by an empty path. A type or label is never split on a
+// separator character, so - unlike the "/"-joined paths hclConverter builds for its own
+// internal bookkeeping - labels containing arbitrary characters (including "_" or "/")
+// round-trip exactly.
+type hclEditor struct {
+	file *hclwrite.File
+}
+
+// newHclEditor parses src (the raw bytes of an HCL file) into an editable hclwrite.File.
+func newHclEditor(src []byte, filename string) (*hclEditor, hcl.Diagnostics) {
+	file, diags := hclwrite.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return &hclEditor{file: file}, nil
+}
+
+// blockTypeAndLabels extracts the type and labels from a block address map of the shape
+// {"type": "...", "labels": [...]}, as used both for path segments and for the block
+// argument to AppendBlock.
+func blockTypeAndLabels(addr map[string]interface{}) (blockType string, labels []string) {
+	blockType, _ = addr["type"].(string)
+	rawLabels, _ := addr["labels"].([]interface{})
+	labels = make([]string, len(rawLabels))
+	for i, l := range rawLabels {
+		labels[i], _ = l.(string)
+	}
+	return blockType, labels
+}
+
+// body navigates from the file's root body down to the block addressed by path, returning
+// nil if any segment along the way does not match an existing block.
+func (editor *hclEditor) body(path []map[string]interface{}) *hclwrite.Body {
+	body := editor.file.Body()
+	for _, addr := range path {
+		blockType, labels := blockTypeAndLabels(addr)
+		block := body.FirstMatchingBlock(blockType, labels)
+		if block == nil {
+			return nil
+		}
+		body = block.Body()
+	}
+	return body
+}
+
+// SetAttribute sets (or creates) the attribute named attrName, on the block addressed by
+// path, to value. value is a plain JS-shaped value (string, float64, bool, []interface{} or
+// map[string]interface{}, possibly nested).
+func (editor *hclEditor) SetAttribute(path []map[string]interface{}, attrName string, value interface{}) bool {
+	body := editor.body(path)
+	if body == nil || attrName == "" {
+		return false
+	}
+	body.SetAttributeValue(attrName, ctyValueFromJS(value))
+	return true
+}
+
+// AppendBlock appends a new block under the body addressed by path. block uses the same
+// shape hclConverter emits for a block node (see exitBlock): {"type": "...", "labels": [...]}.
+func (editor *hclEditor) AppendBlock(path []map[string]interface{}, block map[string]interface{}) bool {
+	body := editor.body(path)
+	if body == nil {
+		return false
+	}
+
+	blockType, labels := blockTypeAndLabels(block)
+	if blockType == "" {
+		return false
+	}
+
+	body.AppendNewBlock(blockType, labels)
+	return true
+}
+
+// RemoveBlock removes the block addressed by path.
+func (editor *hclEditor) RemoveBlock(path []map[string]interface{}) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	body := editor.body(path[:len(path)-1])
+	if body == nil {
+		return false
+	}
+
+	blockType, labels := blockTypeAndLabels(path[len(path)-1])
+	block := body.FirstMatchingBlock(blockType, labels)
+	if block == nil {
+		return false
+	}
+	return body.RemoveBlock(block)
+}
+
+// Bytes renders the (possibly edited) file back out. Anything that was not touched keeps
+// its original formatting, comments and blank lines.
+func (editor *hclEditor) Bytes() []byte {
+	return editor.file.Bytes()
+}
+
+// ctyValueFromJS converts a plain JS-shaped value, as produced by JSON.parse on the JS side,
+// into the cty.Value hclwrite needs to render a new attribute expression. This is the
+// reverse of what convertValue does when converting a parsed HCL value out to JS.
+func ctyValueFromJS(value interface{}) cty.Value {
+	switch v := value.(type) {
+	case nil:
+		return cty.NullVal(cty.DynamicPseudoType)
+	case string:
+		return cty.StringVal(v)
+	case bool:
+		return cty.BoolVal(v)
+	case float64:
+		return cty.NumberFloatVal(v)
+	case []interface{}:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal
+		}
+		vals := make([]cty.Value, len(v))
+		for i, elem := range v {
+			vals[i] = ctyValueFromJS(elem)
+		}
+		return cty.TupleVal(vals)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal
+		}
+		vals := map[string]cty.Value{}
+		for k, elem := range v {
+			vals[k] = ctyValueFromJS(elem)
+		}
+		return cty.ObjectVal(vals)
+	default:
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const editorFixture = `
+resource "aws_instance" "web_server" {
+  ami = "abc"
+}
+`
+
+func TestHclEditorBodyHandlesUnderscoresInLabels(t *testing.T) {
+	editor, diags := newHclEditor([]byte(editorFixture), "test.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	path := []map[string]interface{}{
+		{"type": "resource", "labels": []interface{}{"aws_instance", "web_server"}},
+	}
+
+	if body := editor.body(path); body == nil {
+		t.Fatalf("expected to find resource.aws_instance.web_server, got nil body")
+	}
+}
+
+func TestHclEditorSetAttribute(t *testing.T) {
+	editor, diags := newHclEditor([]byte(editorFixture), "test.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	path := []map[string]interface{}{
+		{"type": "resource", "labels": []interface{}{"aws_instance", "web_server"}},
+	}
+
+	if ok := editor.SetAttribute(path, "ami", "def"); !ok {
+		t.Fatalf("expected SetAttribute to succeed")
+	}
+
+	if out := string(editor.Bytes()); !strings.Contains(out, `ami = "def"`) {
+		t.Fatalf("expected rewritten ami attribute, got:\n%s", out)
+	}
+}
+
+func TestHclEditorRemoveBlock(t *testing.T) {
+	editor, diags := newHclEditor([]byte(editorFixture), "test.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	path := []map[string]interface{}{
+		{"type": "resource", "labels": []interface{}{"aws_instance", "web_server"}},
+	}
+
+	if ok := editor.RemoveBlock(path); !ok {
+		t.Fatalf("expected RemoveBlock to succeed")
+	}
+
+	if editor.body(path) != nil {
+		t.Fatalf("expected block to be gone after RemoveBlock")
+	}
+}
+
+func TestHclEditorAppendBlock(t *testing.T) {
+	editor, diags := newHclEditor([]byte(editorFixture), "test.tf")
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse diagnostics: %s", diags.Error())
+	}
+
+	ok := editor.AppendBlock(nil, map[string]interface{}{
+		"type":   "resource",
+		"labels": []interface{}{"aws_instance", "db"},
+	})
+	if !ok {
+		t.Fatalf("expected AppendBlock to succeed")
+	}
+
+	path := []map[string]interface{}{
+		{"type": "resource", "labels": []interface{}{"aws_instance", "db"}},
+	}
+	if editor.body(path) == nil {
+		t.Fatalf("expected the newly appended block to be found by address")
+	}
+}
+
+func TestBlockTypeAndLabels(t *testing.T) {
+	blockType, labels := blockTypeAndLabels(map[string]interface{}{
+		"type":   "resource",
+		"labels": []interface{}{"aws_instance", "web_server"},
+	})
+	if blockType != "resource" {
+		t.Fatalf("expected type %q, got %q", "resource", blockType)
+	}
+	if len(labels) != 2 || labels[0] != "aws_instance" || labels[1] != "web_server" {
+		t.Fatalf("expected labels [aws_instance web_server], got %v", labels)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	hcljson "github.com/hashicorp/hcl2/hcl/json"
+)
+
+// jsonBlockSchema tells convertJSONBody which top-level keys are block types and how many
+// labels each one takes, e.g. {"resource": 2, "variable": 1}. This is the schema hint JSON
+// HCL needs in order to tell a block apart from a plain object-valued attribute - see
+// hashicorp/hcl2/hcl/json/spec.md. Keys not present here are decoded as plain attributes.
+type jsonBlockSchema map[string]int
+
+// ParseJSONFile parses a .tf.json / .hcl.json file and converts it into the same "attrs"/
+// "blocks"/"diagnostics" JS shape hclConverter produces for native ".tf" syntax, so
+// downstream JS consumers can work uniformly across both formats. Unlike hclConverter, which
+// walks the hclsyntax-specific AST via hclsyntax.Walk, JSON bodies only expose themselves
+// through the generic hcl.Body interface, so this drives the conversion through
+// hcl.Body.PartialContent and JustAttributes instead, reusing hclConverter's own value and
+// diagnostics rendering along the way.
+func ParseJSONFile(src []byte, filename string, ctx *hcl.EvalContext, schema jsonBlockSchema) (map[string]interface{}, hcl.Diagnostics) {
+	file, diags := hcljson.Parse(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	root := NewHclConverter(src, ctx)
+	rootRange := hcl.Range{Filename: filename, Start: hcl.InitialPos, End: hcl.InitialPos}
+	bodyDiags, _ := convertJSONBody(root, file.Body, schema, rootRange)
+	diags = append(diags, bodyDiags...)
+	// convertJSONBody only returns its diagnostics; fold them into the shared diags pointer
+	// (alongside whatever maker.convertValue already recorded there directly) so they show up
+	// in root.Diagnostics(), the same place a native-syntax parse failure would.
+	*root.diags = append(*root.diags, diags...)
+	root.JSValue["diagnostics"] = root.Diagnostics()
+	return root.JSValue, diags
+}
+
+// rangeUnion returns the smallest range covering both a and b. convertJSONBody uses it to grow
+// a block's header-only DefRange into one spanning its whole body, the JSON equivalent of what
+// hclsyntax.Block.Range() gives exitBlock for free from braces in the source text.
+func rangeUnion(a, b hcl.Range) hcl.Range {
+	start := a.Start
+	if b.Start.Byte < start.Byte {
+		start = b.Start
+	}
+	end := a.End
+	if b.End.Byte > end.Byte {
+		end = b.End
+	}
+	return hcl.Range{Filename: a.Filename, Start: start, End: end}
+}
+
+// convertJSONBody fills maker's "attrs" and "blocks" from body, recursing into every block
+// named in schema. schema only ever describes the outermost body's keys (matching its own
+// "top-level keys" doc comment): it is not reapplied to nested block bodies, so an attribute
+// inside a block that happens to share a name with a root block type is never mistaken for
+// one. The caller is responsible for folding the returned diagnostics into maker.diags, the
+// same way ParseJSONFile does, so they end up in the root's JS-facing "diagnostics" key too.
+// defRange seeds the range this body's content is unioned into as it is discovered, and the
+// resulting whole-body range is returned alongside the diagnostics so a caller converting a
+// block can use it for "source" instead of the block's header-only DefRange.
+func convertJSONBody(maker *hclConverter, body hcl.Body, schema jsonBlockSchema, defRange hcl.Range) (hcl.Diagnostics, hcl.Range) {
+	maker.enterBody()
+	bodyRange := defRange
+
+	bodySchema := &hcl.BodySchema{}
+	for blockType, labelCount := range schema {
+		bodySchema.Blocks = append(bodySchema.Blocks, hcl.BlockHeaderSchema{
+			Type:       blockType,
+			LabelNames: make([]string, labelCount),
+		})
+	}
+
+	content, remain, diags := body.PartialContent(bodySchema)
+
+	for _, blk := range content.Blocks {
+		pathAddition := blk.Type
+		for _, label := range blk.Labels {
+			pathAddition += "_" + label
+		}
+		nested := newHclConverter(maker.path+"/"+pathAddition, maker.ctx, maker.src, maker.diags)
+		blockDiags, blockRange := convertJSONBody(nested, blk.Body, nil, blk.DefRange)
+		diags = append(diags, blockDiags...)
+		bodyRange = rangeUnion(bodyRange, blockRange)
+
+		nested.JSValue["type"] = blk.Type
+		labels := make([]interface{}, len(blk.Labels))
+		for i, label := range blk.Labels {
+			labels[i] = label
+		}
+		nested.JSValue["labels"] = labels
+		nested.JSValue["source"] = convertSourceRange(blockRange)
+		maker.JSValue["blocks"] = append(maker.JSValue["blocks"].([]interface{}), nested.JSValue)
+	}
+
+	rawAttrs, attrDiags := remain.JustAttributes()
+	diags = append(diags, attrDiags...)
+
+	for name, attr := range rawAttrs {
+		bodyRange = rangeUnion(bodyRange, attr.Range)
+
+		exprJS, exprDiags := maker.classifyJSONExpression(attr.Expr, maker.path+"."+name)
+		diags = append(diags, exprDiags...)
+
+		maker.JSValue["attrs"].(map[string]interface{})[name] = map[string]interface{}{
+			"source":      convertSourceRange(attr.Range),
+			"expressions": []interface{}{exprJS},
+		}
+	}
+
+	return diags, bodyRange
+}
+
+// classifyJSONExpression converts a JSON attribute's expression into the same node shape
+// exitEvaluatedExpression produces for a reference-bearing native expression, rather than
+// unconditionally flattening it to a "literal". Unlike hclsyntax, hcl/json gives no
+// expression tree to walk - a "${var.foo}" interpolation is one opaque expression, not a
+// ScopeTraversalExpr - so there is no finer-grained node type to report than "literal" (no
+// references) or "scope_traversal" (references present), but that is enough for the JS side to
+// tell a plain value from one that depends on something else, the same way it can for native
+// syntax.
+func (maker *hclConverter) classifyJSONExpression(expr hcl.Expression, path string) (map[string]interface{}, hcl.Diagnostics) {
+	references := collectReferences(expr)
+	val, diags := expr.Value(maker.ctx)
+
+	if len(references) == 0 {
+		return map[string]interface{}{
+			"type":        "literal",
+			"value":       maker.convertValue(val, path, expr.Range()),
+			"expressions": []interface{}{},
+		}, diags
+	}
+
+	result := map[string]interface{}{
+		"type":        "scope_traversal",
+		"references":  references,
+		"expressions": []interface{}{},
+	}
+	if !diags.HasErrors() && val.IsWhollyKnown() && !val.Type().HasDynamicTypes() && !val.IsNull() {
+		result["value"] = maker.convertValue(val, path, expr.Range())
+	}
+	return result, diags
+}
@@ -1,38 +1,57 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/hashicorp/hcl2/hcl"
 	"github.com/hashicorp/hcl2/hcl/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 )
 
 // convertValue converts a cty.Value to the appropriate go native type so that it can be
-// serialized to javascript
-func convertValue(val cty.Value, path string) interface{} {
+// serialized to javascript. Anything that cannot be converted is reported via maker's
+// diagnostics rather than panicking, so a single unsupported value does not crash the
+// conversion of the rest of the file.
+func (maker *hclConverter) convertValue(val cty.Value, path string, rng hcl.Range) interface{} {
 	t := val.Type()
 	switch {
 	case t.HasDynamicTypes():
 		// Dynamic type means this is an expression that has external references
 		// We do not support this scenario yet but for now we also don't want to crash
-		return "*** dynamic ***"
+		maker.addDiagnostic(hcl.DiagWarning, "Unresolved dynamic value",
+			fmt.Sprintf("The value at %s has external references that could not be resolved and is omitted from the output.", path), rng)
+		return nil
 
 	case !val.IsKnown():
 		// This can happen with "<<EOF" type expressions that also reference variables
 		// We do not support this scenario yet but for now we also don't want to crash
-		return "*** unknown ***"
+		maker.addDiagnostic(hcl.DiagWarning, "Unresolved value",
+			fmt.Sprintf("The value at %s depends on values that are not yet known and is omitted from the output.", path), rng)
+		return nil
+
+	case val.IsNull():
+		// A concretely-typed null (e.g. from `x = true ? null : "x"`) is wholly known and has
+		// no dynamic types, so it reaches here rather than one of the two cases above. None of
+		// AsString/AsBigFloat/True/AsValueMap tolerate a null value, so it must be handled
+		// before any of the type-dependent branches below, regardless of t.
+		maker.addDiagnostic(hcl.DiagWarning, "Null value",
+			fmt.Sprintf("The value at %s is null.", path), rng)
+		return nil
 
 	case t.IsTupleType():
 		res := make([]interface{}, val.LengthInt())
 		var i int64
 		for i = 0; i < int64(val.LengthInt()); i++ {
-			res[i] = convertValue(val.Index(cty.NumberIntVal(i)), path+"."+string(i))
+			res[i] = maker.convertValue(val.Index(cty.NumberIntVal(i)), path+"."+strconv.FormatInt(i, 10), rng)
 		}
 		return res
 
 	case t.IsObjectType():
 		res := map[string]interface{}{}
 		for k, v := range val.AsValueMap() {
-			res[k] = convertValue(v, path+"."+k)
+			res[k] = maker.convertValue(v, path+"."+k, rng)
 		}
 		return res
 
@@ -46,18 +65,26 @@ func convertValue(val cty.Value, path string) interface{} {
 		case cty.Bool:
 			return val.True()
 		default:
-			panic("unknown cty primitve type: " + t.FriendlyName() + " at " + path)
+			maker.addDiagnostic(hcl.DiagError, "Unsupported primitive type",
+				fmt.Sprintf("Cannot convert cty primitive type %s at %s to a JS value.", t.FriendlyName(), path), rng)
+			return nil
 		}
 
 	// We should never get the following types from parsing since they will be parsed as less specific types
 	// see https://github.com/hashicorp/hcl2/blob/master/hcl/hclsyntax/spec.md#collection-values
 	case t.IsListType():
-		panic("lists are not expected here - we expect to get tuple type instead")
+		maker.addDiagnostic(hcl.DiagError, "Unexpected list type",
+			fmt.Sprintf("Expected a tuple type at %s, got a list; this indicates a bug in the HCL parser or converter.", path), rng)
+		return nil
 	case t.IsMapType():
-		panic("maps are not expected here - we expect to get an object type instead")
+		maker.addDiagnostic(hcl.DiagError, "Unexpected map type",
+			fmt.Sprintf("Expected an object type at %s, got a map; this indicates a bug in the HCL parser or converter.", path), rng)
+		return nil
 	}
 
-	panic("unknown type to convert: " + t.FriendlyName() + " at " + path)
+	maker.addDiagnostic(hcl.DiagError, "Unsupported value type",
+		fmt.Sprintf("Cannot convert value of type %s at %s to a JS value.", t.FriendlyName(), path), rng)
+	return nil
 }
 
 func convertPos(pos hcl.Pos) map[string]interface{} {
@@ -82,13 +109,48 @@ type hclConverter struct {
 	path    string
 	JSValue map[string]interface{}
 
+	// ctx is used to resolve variable references and function calls while converting
+	// expressions. It may be nil, in which case such expressions are emitted as
+	// references only, with no resolved "value".
+	ctx *hcl.EvalContext
+
+	// src holds the raw bytes of the file being converted, used to render source snippets
+	// for diagnostics. It may be nil, in which case diagnostics are emitted without a snippet.
+	src []byte
+
+	// diags accumulates every diagnostic raised while converting the tree rooted at this
+	// converter. It is shared (via pointer) with every converter nested under it, so
+	// diagnostics raised deep in the tree surface on the root's Diagnostics().
+	diags *hcl.Diagnostics
+
+	// isRoot is true only for the converter returned by NewHclConverter, so the top-level
+	// "diagnostics" key is emitted exactly once, on the outermost body.
+	isRoot bool
+
 	nestedConverter *hclConverter
 }
 
-func newHclConverter(path string) *hclConverter {
+// NewHclConverter creates the root converter for a single HCL file. src is the raw file
+// bytes (used to render diagnostic snippets) and ctx is the evaluation context used to
+// resolve variable references and function calls; both may be nil.
+func NewHclConverter(src []byte, ctx *hcl.EvalContext) *hclConverter {
+	return &hclConverter{
+		path:    "",
+		JSValue: map[string]interface{}{},
+		ctx:     ctx,
+		src:     src,
+		diags:   &hcl.Diagnostics{},
+		isRoot:  true,
+	}
+}
+
+func newHclConverter(path string, ctx *hcl.EvalContext, src []byte, diags *hcl.Diagnostics) *hclConverter {
 	return &hclConverter{
 		path:            path,
 		JSValue:         map[string]interface{}{},
+		ctx:             ctx,
+		src:             src,
+		diags:           diags,
 		nestedConverter: nil,
 	}
 }
@@ -103,7 +165,7 @@ func (maker *hclConverter) enterBlock(blk *hclsyntax.Block) {
 	for _, l := range blk.Labels {
 		pathAddition += "_" + l
 	}
-	maker.nestedConverter = newHclConverter(maker.path + "/" + pathAddition)
+	maker.nestedConverter = newHclConverter(maker.path+"/"+pathAddition, maker.ctx, maker.src, maker.diags)
 }
 
 func (maker *hclConverter) exitBlock(blk *hclsyntax.Block) {
@@ -120,7 +182,7 @@ func (maker *hclConverter) exitBlock(blk *hclsyntax.Block) {
 }
 
 func (maker *hclConverter) enterExpression(expType string) {
-	maker.nestedConverter = newHclConverter(maker.path + "/" + expType)
+	maker.nestedConverter = newHclConverter(maker.path+"/"+expType, maker.ctx, maker.src, maker.diags)
 	maker.nestedConverter.JSValue["expressions"] = []interface{}{}
 }
 
@@ -138,21 +200,201 @@ func (maker *hclConverter) exitExpression(expType string) {
 	maker.nestedConverter = nil
 }
 
-func (maker *hclConverter) exitLiteralExpression(val cty.Value) {
+func (maker *hclConverter) exitLiteralExpression(val cty.Value, rng hcl.Range) {
+	nested := maker.nestedConverter
 	maker.appendExpression(map[string]interface{}{
 		"type":  "literal",
-		"value": convertValue(val, maker.nestedConverter.path),
+		"value": nested.convertValue(val, nested.path, rng),
 		// Every expression need to have subexpressions
 		"expressions": []interface{}{},
 	})
 	maker.nestedConverter = nil
 }
 
+// addDiagnostic records a diagnostic against the converter's shared diagnostics slice, to be
+// rendered and exposed later via Diagnostics().
+func (maker *hclConverter) addDiagnostic(severity hcl.DiagnosticSeverity, summary, detail string, subject hcl.Range) {
+	if maker.diags == nil {
+		return
+	}
+	*maker.diags = append(*maker.diags, &hcl.Diagnostic{
+		Severity: severity,
+		Summary:  summary,
+		Detail:   detail,
+		Subject:  &subject,
+	})
+}
+
+func diagnosticSeverityString(severity hcl.DiagnosticSeverity) string {
+	switch severity {
+	case hcl.DiagError:
+		return "error"
+	case hcl.DiagWarning:
+		return "warning"
+	default:
+		return "invalid"
+	}
+}
+
+// renderSnippet renders the source line the given range starts on, with a caret underlining
+// the offending bytes, in the same spirit as Terraform's command/format.Diagnostic renderer.
+func (maker *hclConverter) renderSnippet(rng hcl.Range) string {
+	if maker.src == nil {
+		return ""
+	}
+	lines := strings.Split(string(maker.src), "\n")
+	lineIdx := rng.Start.Line - 1
+	if lineIdx < 0 || lineIdx >= len(lines) {
+		return ""
+	}
+	line := lines[lineIdx]
+
+	startCol := rng.Start.Column
+	if startCol < 1 {
+		startCol = 1
+	}
+	endCol := rng.End.Column
+	if rng.End.Line != rng.Start.Line || endCol <= startCol {
+		endCol = len(line) + 1
+	}
+	caretLen := endCol - startCol
+	if caretLen < 1 {
+		caretLen = 1
+	}
+
+	prefix := fmt.Sprintf("%d: ", rng.Start.Line)
+	caret := strings.Repeat(" ", len(prefix)+startCol-1) + strings.Repeat("^", caretLen)
+	return fmt.Sprintf("%s%s\n%s", prefix, line, caret)
+}
+
+// renderDiagnostic converts a single hcl.Diagnostic into the JSON-serializable form used on
+// the JS side: severity, summary, detail, the subject range and a rendered source snippet.
+func (maker *hclConverter) renderDiagnostic(diag *hcl.Diagnostic) map[string]interface{} {
+	res := map[string]interface{}{
+		"severity": diagnosticSeverityString(diag.Severity),
+		"summary":  diag.Summary,
+		"detail":   diag.Detail,
+	}
+	if diag.Subject != nil {
+		res["source"] = convertSourceRange(*diag.Subject)
+		res["snippet"] = maker.renderSnippet(*diag.Subject)
+	}
+	return res
+}
+
+// Diagnostics returns every diagnostic accumulated while converting the tree rooted at this
+// converter (e.g. unresolved references or unsupported value types), rendered for the JS side.
+func (maker *hclConverter) Diagnostics() []interface{} {
+	if maker.diags == nil {
+		return []interface{}{}
+	}
+	rendered := make([]interface{}, len(*maker.diags))
+	for i, diag := range *maker.diags {
+		rendered[i] = maker.renderDiagnostic(diag)
+	}
+	return rendered
+}
+
+// exitBody finalizes the JSValue for a body. On the outermost body this attaches the
+// "diagnostics" key so parse/eval problems travel alongside the converted tree instead of
+// crashing the Go process.
+func (maker *hclConverter) exitBody() {
+	if maker.isRoot {
+		maker.JSValue["diagnostics"] = maker.Diagnostics()
+	}
+}
+
+// referencesForTraversal unwraps a (possibly multi-step) traversal into every one of its
+// prefixes from the second step on, e.g. "aws_instance.foo.id" becomes
+// ["aws_instance.foo", "aws_instance.foo.id"]. This mirrors the "references" shape
+// Terraform's jsonconfig.expression produces, so the JavaScript side can filter on a
+// reference without having to re-parse the traversal itself. The bare root on its own
+// ("aws_instance") is not a valid reference - it would match every instance of the type - so
+// it is never included.
+func referencesForTraversal(trav hcl.Traversal) []interface{} {
+	var parts []string
+	refs := make([]interface{}, 0, len(trav))
+	for _, step := range trav {
+		switch t := step.(type) {
+		case hcl.TraverseRoot:
+			parts = append(parts, t.Name)
+		case hcl.TraverseAttr:
+			parts = append(parts, t.Name)
+		case hcl.TraverseIndex:
+			parts = append(parts, fmt.Sprintf("[%s]", indexKeyString(t.Key)))
+		default:
+			continue
+		}
+		if len(parts) < 2 {
+			continue
+		}
+		refs = append(refs, strings.Join(parts, "."))
+	}
+	return refs
+}
+
+func indexKeyString(key cty.Value) string {
+	switch key.Type() {
+	case cty.String:
+		return key.AsString()
+	case cty.Number:
+		f, _ := key.AsBigFloat().Float64()
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	default:
+		return "?"
+	}
+}
+
+// collectReferences gathers every variable reference an expression depends on, expanded
+// into reference chains via referencesForTraversal. Always returns a non-nil slice (even
+// when empty) so it serializes as JSON "[]" rather than "null", matching "expressions". Takes
+// the base hcl.Expression interface, not hclsyntax.Expression, so it also works on the opaque
+// expression type hcl/json hands out for JSON-syntax attributes.
+func collectReferences(expr hcl.Expression) []interface{} {
+	refs := []interface{}{}
+	for _, trav := range expr.Variables() {
+		refs = append(refs, referencesForTraversal(trav)...)
+	}
+	return refs
+}
+
+// exitEvaluatedExpression handles the expression types that can reference variables and
+// function calls (ScopeTraversalExpr, FunctionCallExpr, ConditionalExpr, ...). It attempts
+// to resolve the expression against maker.ctx: when that succeeds with no unknowns, the
+// resolved constant value is attached under "value"; either way the chain of references the
+// expression depends on is attached under "references" so the JS side can work with it
+// without evaluating HCL itself. A constant-folded null (e.g. `true ? null : "x"`) is
+// wholly known and has no dynamic types, so it is excluded explicitly here too - convertValue
+// already handles it safely, but there is no reason to route a null through it via "value"
+// when omitting the key entirely is just as valid, and matches how an unresolved value is
+// already handled above.
+func (maker *hclConverter) exitEvaluatedExpression(expType string, expr hclsyntax.Expression) hcl.Diagnostics {
+	nested := maker.nestedConverter
+	result := map[string]interface{}{
+		"type":        expType,
+		"expressions": nested.JSValue["expressions"],
+		"references":  collectReferences(expr),
+	}
+
+	val, diags := expr.Value(maker.ctx)
+	if !diags.HasErrors() && val.IsWhollyKnown() && !val.Type().HasDynamicTypes() && !val.IsNull() {
+		result["value"] = nested.convertValue(val, nested.path, expr.Range())
+	}
+
+	maker.appendExpression(result)
+	maker.nestedConverter = nil
+	return hcl.Diagnostics{}
+}
+
 func (maker *hclConverter) exitAttribute(attr *hclsyntax.Attribute) {
-	maker.JSValue["attrs"].(map[string]interface{})[attr.Name] = map[string]interface{}{
+	attrJS := map[string]interface{}{
 		"source":      convertSourceRange(attr.Range()),
 		"expressions": maker.nestedConverter.JSValue["expressions"],
 	}
+	if typeDesc := maker.exitTypeExpression(attr); typeDesc != nil {
+		attrJS["type"] = typeDesc
+	}
+	maker.JSValue["attrs"].(map[string]interface{})[attr.Name] = attrJS
 	maker.nestedConverter = nil
 }
 
@@ -196,6 +438,33 @@ func (maker *hclConverter) Enter(node hclsyntax.Node) hcl.Diagnostics {
 
 	case *hclsyntax.LiteralValueExpr:
 		maker.enterExpression("literal")
+
+	case *hclsyntax.ScopeTraversalExpr:
+		maker.enterExpression("scope_traversal")
+
+	case *hclsyntax.RelativeTraversalExpr:
+		maker.enterExpression("relative_traversal")
+
+	case *hclsyntax.FunctionCallExpr:
+		maker.enterExpression("function_call")
+
+	case *hclsyntax.ConditionalExpr:
+		maker.enterExpression("conditional")
+
+	case *hclsyntax.BinaryOpExpr:
+		maker.enterExpression("binary_op")
+
+	case *hclsyntax.UnaryOpExpr:
+		maker.enterExpression("unary_op")
+
+	case *hclsyntax.IndexExpr:
+		maker.enterExpression("index")
+
+	case *hclsyntax.SplatExpr:
+		maker.enterExpression("splat")
+
+	case *hclsyntax.ForExpr:
+		maker.enterExpression("for")
 	}
 
 	return hcl.Diagnostics{}
@@ -210,7 +479,7 @@ func (maker *hclConverter) Exit(node hclsyntax.Node) hcl.Diagnostics {
 
 	switch node.(type) {
 	case *hclsyntax.Body:
-		// pass
+		maker.exitBody()
 
 	case hclsyntax.Blocks:
 		// pass
@@ -239,15 +508,42 @@ func (maker *hclConverter) Exit(node hclsyntax.Node) hcl.Diagnostics {
 	case *hclsyntax.ObjectConsKeyExpr:
 		exp := node.(*hclsyntax.ObjectConsKeyExpr)
 		val, evalErrs := exp.Value(nil)
-		maker.exitLiteralExpression(val)
+		maker.exitLiteralExpression(val, exp.Range())
 		return evalErrs
 
 	case *hclsyntax.LiteralValueExpr:
 		exp := node.(*hclsyntax.LiteralValueExpr)
 		val, evalErrs := exp.Value(nil)
-		maker.exitLiteralExpression(val)
+		maker.exitLiteralExpression(val, exp.Range())
 		return evalErrs
 
+	case *hclsyntax.ScopeTraversalExpr:
+		return maker.exitEvaluatedExpression("scope_traversal", node.(*hclsyntax.ScopeTraversalExpr))
+
+	case *hclsyntax.RelativeTraversalExpr:
+		return maker.exitEvaluatedExpression("relative_traversal", node.(*hclsyntax.RelativeTraversalExpr))
+
+	case *hclsyntax.FunctionCallExpr:
+		return maker.exitEvaluatedExpression("function_call", node.(*hclsyntax.FunctionCallExpr))
+
+	case *hclsyntax.ConditionalExpr:
+		return maker.exitEvaluatedExpression("conditional", node.(*hclsyntax.ConditionalExpr))
+
+	case *hclsyntax.BinaryOpExpr:
+		return maker.exitEvaluatedExpression("binary_op", node.(*hclsyntax.BinaryOpExpr))
+
+	case *hclsyntax.UnaryOpExpr:
+		return maker.exitEvaluatedExpression("unary_op", node.(*hclsyntax.UnaryOpExpr))
+
+	case *hclsyntax.IndexExpr:
+		return maker.exitEvaluatedExpression("index", node.(*hclsyntax.IndexExpr))
+
+	case *hclsyntax.SplatExpr:
+		return maker.exitEvaluatedExpression("splat", node.(*hclsyntax.SplatExpr))
+
+	case *hclsyntax.ForExpr:
+		return maker.exitEvaluatedExpression("for", node.(*hclsyntax.ForExpr))
+
 	}
 	return hcl.Diagnostics{}
 }
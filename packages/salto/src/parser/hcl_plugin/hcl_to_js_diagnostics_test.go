@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestConvertValueTupleIndexPathIsDecimal(t *testing.T) {
+	root := NewHclConverter(nil, nil)
+	tuple := cty.TupleVal([]cty.Value{
+		cty.StringVal("known"),
+		cty.UnknownVal(cty.String),
+	})
+
+	root.convertValue(tuple, "root", hcl.Range{})
+
+	rendered := root.Diagnostics()
+	if len(rendered) != 1 {
+		t.Fatalf("expected exactly one diagnostic, got %d: %v", len(rendered), rendered)
+	}
+
+	detail, _ := rendered[0].(map[string]interface{})["detail"].(string)
+	if !strings.Contains(detail, "root.1") {
+		t.Fatalf("expected diagnostic detail to reference path %q, got %q", "root.1", detail)
+	}
+}
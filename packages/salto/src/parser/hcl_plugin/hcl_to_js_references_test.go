@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// literalExprForTest parses a trivial literal attribute and returns its expression, for
+// tests that need a real hclsyntax.Expression rather than a hand-built one.
+func literalExprForTest(t *testing.T) hclsyntax.Expression {
+	t.Helper()
+	f, diags := hclsyntax.ParseConfig([]byte(`x = "hello"`), "test.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("unexpected parse error: %s", diags.Error())
+	}
+	body := f.Body.(*hclsyntax.Body)
+	return body.Attributes["x"].Expr
+}
+
+func TestReferencesForTraversalDropsBareRoot(t *testing.T) {
+	trav := hcl.Traversal{
+		hcl.TraverseRoot{Name: "aws_instance"},
+		hcl.TraverseAttr{Name: "foo"},
+		hcl.TraverseAttr{Name: "id"},
+	}
+
+	got := referencesForTraversal(trav)
+	want := []interface{}{"aws_instance.foo", "aws_instance.foo.id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestReferencesForTraversalSingleStepIsEmpty(t *testing.T) {
+	trav := hcl.Traversal{hcl.TraverseRoot{Name: "var"}}
+
+	got := referencesForTraversal(trav)
+	if len(got) != 0 {
+		t.Fatalf("expected no references for a bare root, got %v", got)
+	}
+}
+
+func TestCollectReferencesNeverReturnsNil(t *testing.T) {
+	got := collectReferences(literalExprForTest(t))
+	if got == nil {
+		t.Fatalf("expected a non-nil (possibly empty) slice")
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no references for a literal expression, got %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+// decodeTypeExpression recognizes an expression written using the convention from HCL's
+// typeexpr extension - e.g. "string", "list(string)", "object({name=string, age=number})" -
+// and produces a JSON-serializable type descriptor for it, such as
+// {"kind": "list", "elem": {"kind": "string"}}. It returns ok=false when expr is not shaped
+// like a type expression at all, so callers can fall back to treating it as an ordinary value.
+func decodeTypeExpression(expr hclsyntax.Expression) (descriptor map[string]interface{}, ok bool) {
+	if keyword := hcl.ExprAsKeyword(expr); keyword != "" {
+		switch keyword {
+		case "any", "string", "number", "bool":
+			return map[string]interface{}{"kind": keyword}, true
+		}
+		return nil, false
+	}
+
+	call, diags := hcl.ExprCall(expr)
+	if diags.HasErrors() || len(call.Arguments) != 1 {
+		return nil, false
+	}
+	arg := call.Arguments[0]
+
+	switch call.Name {
+	case "list", "set", "map":
+		elem, ok := decodeTypeExpression(arg.(hclsyntax.Expression))
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{"kind": call.Name, "elem": elem}, true
+
+	case "tuple":
+		tuple, isTuple := arg.(*hclsyntax.TupleConsExpr)
+		if !isTuple {
+			return nil, false
+		}
+		elems := make([]interface{}, len(tuple.Exprs))
+		for i, elemExpr := range tuple.Exprs {
+			elem, ok := decodeTypeExpression(elemExpr)
+			if !ok {
+				return nil, false
+			}
+			elems[i] = elem
+		}
+		return map[string]interface{}{"kind": "tuple", "elems": elems}, true
+
+	case "object":
+		obj, isObj := arg.(*hclsyntax.ObjectConsExpr)
+		if !isObj {
+			return nil, false
+		}
+		attrs := map[string]interface{}{}
+		for _, item := range obj.Items {
+			name := hcl.ExprAsKeyword(item.KeyExpr)
+			if name == "" {
+				return nil, false
+			}
+			attrType, ok := decodeTypeExpression(item.ValueExpr)
+			if !ok {
+				return nil, false
+			}
+			attrs[name] = attrType
+		}
+		return map[string]interface{}{"kind": "object", "attrs": attrs}, true
+	}
+
+	return nil, false
+}
+
+// exitTypeExpression attempts to decode an attribute's expression as a schema type
+// expression, returning nil when the expression does not parse as one. The resulting
+// descriptor is attached to the attribute's JS node under a "type" key, letting the JS
+// layer render form editors from user-declared schemas such as `type = list(string)`.
+func (maker *hclConverter) exitTypeExpression(attr *hclsyntax.Attribute) map[string]interface{} {
+	descriptor, ok := decodeTypeExpression(attr.Expr)
+	if !ok {
+		return nil
+	}
+	return descriptor
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+)
+
+func TestDecodeTypeExpression(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want map[string]interface{}
+	}{
+		{
+			name: "primitive",
+			src:  `x = string`,
+			want: map[string]interface{}{"kind": "string"},
+		},
+		{
+			name: "list",
+			src:  `x = list(string)`,
+			want: map[string]interface{}{
+				"kind": "list",
+				"elem": map[string]interface{}{"kind": "string"},
+			},
+		},
+		{
+			name: "map of bool",
+			src:  `x = map(bool)`,
+			want: map[string]interface{}{
+				"kind": "map",
+				"elem": map[string]interface{}{"kind": "bool"},
+			},
+		},
+		{
+			name: "tuple",
+			src:  `x = tuple([string, number])`,
+			want: map[string]interface{}{
+				"kind": "tuple",
+				"elems": []interface{}{
+					map[string]interface{}{"kind": "string"},
+					map[string]interface{}{"kind": "number"},
+				},
+			},
+		},
+		{
+			name: "object",
+			src:  `x = object({name=string, age=number})`,
+			want: map[string]interface{}{
+				"kind": "object",
+				"attrs": map[string]interface{}{
+					"name": map[string]interface{}{"kind": "string"},
+					"age":  map[string]interface{}{"kind": "number"},
+				},
+			},
+		},
+		{
+			name: "nested",
+			src:  `x = list(object({name=string}))`,
+			want: map[string]interface{}{
+				"kind": "list",
+				"elem": map[string]interface{}{
+					"kind": "object",
+					"attrs": map[string]interface{}{
+						"name": map[string]interface{}{"kind": "string"},
+					},
+				},
+			},
+		},
+		{
+			name: "not a type expression",
+			src:  `x = "hello"`,
+			want: nil,
+		},
+		{
+			name: "unknown keyword",
+			src:  `x = foo`,
+			want: nil,
+		},
+		{
+			name: "unknown call",
+			src:  `x = upper(string)`,
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, diags := hclsyntax.ParseConfig([]byte(tc.src), "test.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatalf("unexpected parse error: %s", diags.Error())
+			}
+			body := f.Body.(*hclsyntax.Body)
+			expr := body.Attributes["x"].Expr
+
+			got, ok := decodeTypeExpression(expr)
+
+			if tc.want == nil {
+				if ok {
+					t.Fatalf("expected decodeTypeExpression to reject this expression, got %#v", got)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("expected decodeTypeExpression to accept this expression")
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}